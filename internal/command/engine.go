@@ -0,0 +1,308 @@
+package command
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/fyne-io/fyne-cross/internal/log"
+	"github.com/fyne-io/fyne-cross/internal/volume"
+)
+
+// containerEngine is implemented by every backend that can run fyne-cross
+// build steps in an isolated environment. Docker and Podman are implemented
+// by newContainerEngine; buildahEngine and buildkitEngine below are
+// rootless-friendly alternatives selected via -engine.
+type containerEngine interface {
+	// createContainerImage returns a containerImage configured to run
+	// build steps for arch/os using baseImage. variant distinguishes
+	// sibling builds of the same arch (e.g. arm/v5, arm/v6, arm/v7) and
+	// must be folded into the returned image's ID so they don't clobber
+	// each other's output paths; pass "" when arch has no variant.
+	createContainerImage(arch Architecture, os string, baseImage string, variant string) containerImage
+	// SupportsPlatform reports whether this engine can build for os/arch,
+	// so unsupported combinations fail fast with a helpful message
+	SupportsPlatform(os string, arch Architecture) bool
+}
+
+// EngineFlag selects the container engine backend used to run build steps
+type EngineFlag string
+
+const (
+	engineDocker   EngineFlag = "docker"
+	enginePodman   EngineFlag = "podman"
+	engineBuildah  EngineFlag = "buildah"
+	engineBuildKit EngineFlag = "buildkit"
+)
+
+// engineSupported lists the -engine values accepted across platform commands
+var engineSupported = []EngineFlag{engineDocker, enginePodman, engineBuildah, engineBuildKit}
+
+// resolveContainerEngine returns the containerEngine for flag. engineDocker,
+// enginePodman and the empty value defer to the existing Docker/Podman
+// selection in newContainerEngine, wrapped in dockerPodmanEngine so it
+// satisfies the SupportsPlatform half of containerEngine too.
+func resolveContainerEngine(ctx Context, flag EngineFlag) (containerEngine, error) {
+	switch flag {
+	case "", engineDocker, enginePodman:
+		runner, err := newContainerEngine(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &dockerPodmanEngine{inner: runner}, nil
+	case engineBuildah:
+		return &buildahEngine{}, nil
+	case engineBuildKit:
+		return &buildkitEngine{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported -engine %q, supported: %s", flag, engineSupported)
+	}
+}
+
+// dockerPodmanEngine adapts the pre-existing Docker/Podman runner returned by
+// newContainerEngine to containerEngine. That runner predates -engine and
+// -platform and knows nothing about variants, so createContainerImage wraps
+// its result in variantImage rather than reaching into its implementation.
+type dockerPodmanEngine struct {
+	inner interface {
+		createContainerImage(arch Architecture, os string, baseImage string) containerImage
+	}
+}
+
+func (e *dockerPodmanEngine) createContainerImage(arch Architecture, os string, baseImage string, variant string) containerImage {
+	image := e.inner.createContainerImage(arch, os, baseImage)
+	if variant == "" {
+		return image
+	}
+	return &variantImage{containerImage: image, variant: variant}
+}
+
+func (e *dockerPodmanEngine) SupportsPlatform(os string, arch Architecture) bool {
+	return os == linuxOS
+}
+
+// variantImage wraps a containerImage whose ID() doesn't account for arch
+// variants (arm/v5, arm/v6, arm/v7, ...), folding the variant into ID() so
+// sibling variant builds get distinct image IDs instead of clobbering each
+// other's tmp/bin/dist output paths, which are all keyed by image.ID().
+type variantImage struct {
+	containerImage
+	variant string
+}
+
+func (i *variantImage) ID() string {
+	return fmt.Sprintf("%s-%s", i.containerImage.ID(), i.variant)
+}
+
+//
+// buildah
+//
+
+// buildahEngine drives build steps with the buildah CLI (`buildah from`
+// to start a working container, `buildah run` for each step) instead of a
+// docker run, letting users build on rootless CI runners (GitHub Actions,
+// GitLab) without a daemon. The working container is never committed to an
+// image with `buildah commit`.
+type buildahEngine struct{}
+
+func (e *buildahEngine) SupportsPlatform(os string, arch Architecture) bool {
+	return os == linuxOS
+}
+
+func (e *buildahEngine) createContainerImage(arch Architecture, os string, baseImage string, variant string) containerImage {
+	return &buildahImage{arch: arch, os: os, base: baseImage, variant: variant, env: map[string]string{}}
+}
+
+// buildahImage is a containerImage backed by a buildah working container,
+// created lazily on the first Run
+type buildahImage struct {
+	arch    Architecture
+	os      string
+	base    string
+	variant string
+	env     map[string]string
+
+	container string
+}
+
+func (i *buildahImage) ID() string {
+	if i.variant == "" {
+		return fmt.Sprintf("%s-%s", i.os, i.arch)
+	}
+	return fmt.Sprintf("%s-%s-%s", i.os, i.arch, i.variant)
+}
+
+func (i *buildahImage) SetEnv(key, value string) {
+	i.env[key] = value
+}
+
+func (i *buildahImage) Run(vol volume.Volume, opts options, cmdArgs []string) error {
+	if i.container == "" {
+		container, err := i.from()
+		if err != nil {
+			return err
+		}
+		i.container = container
+	}
+
+	args := []string{"run"}
+	args = append(args, volumeMountArgs(vol)...)
+	for k, v := range i.env {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", k, v))
+	}
+	if opts.WorkDir != "" {
+		args = append(args, "--workingdir", opts.WorkDir)
+	}
+	args = append(args, i.container, "--")
+	args = append(args, cmdArgs...)
+
+	return runBuildah(args...)
+}
+
+// volumeMountArgs returns the "--volume host:container" pairs that bind vol's
+// work and cache directories into a buildah container, mirroring the mounts
+// newContainerEngine's docker/podman images get via `docker run -v`
+func volumeMountArgs(vol volume.Volume) []string {
+	return []string{
+		"--volume", fmt.Sprintf("%s:%s", vol.WorkDirHost(), vol.WorkDirContainer()),
+		"--volume", fmt.Sprintf("%s:%s", vol.CacheDirHost(), vol.CacheDirContainer()),
+	}
+}
+
+// from starts a new buildah working container from i.base
+func (i *buildahImage) from() (string, error) {
+	out, err := exec.Command("buildah", "from", i.base).Output()
+	if err != nil {
+		return "", fmt.Errorf("could not start a buildah container from %s: %v", i.base, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func runBuildah(args ...string) error {
+	log.Infof("[i] buildah %s", strings.Join(args, " "))
+
+	cmd := exec.Command("buildah", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("buildah %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return nil
+}
+
+//
+// buildkit
+//
+
+// buildkitEngine submits an LLB build graph via buildctl instead of running
+// an interactive container, so the per-arch steps fyne-cross issues can
+// execute in parallel with content-addressed caching between runs.
+type buildkitEngine struct{}
+
+func (e *buildkitEngine) SupportsPlatform(os string, arch Architecture) bool {
+	return os == linuxOS
+}
+
+func (e *buildkitEngine) createContainerImage(arch Architecture, os string, baseImage string, variant string) containerImage {
+	return &buildkitImage{arch: arch, os: os, base: baseImage, variant: variant, env: map[string]string{}}
+}
+
+// buildkitImage is a containerImage that submits one single-RUN-step
+// Dockerfile build per call to Run, each layering on top of the work
+// directory state the previous call exported
+type buildkitImage struct {
+	arch    Architecture
+	os      string
+	base    string
+	variant string
+	env     map[string]string
+	vol     volume.Volume
+}
+
+func (i *buildkitImage) ID() string {
+	if i.variant == "" {
+		return fmt.Sprintf("%s-%s", i.os, i.arch)
+	}
+	return fmt.Sprintf("%s-%s-%s", i.os, i.arch, i.variant)
+}
+
+func (i *buildkitImage) SetEnv(key, value string) {
+	i.env[key] = value
+}
+
+func (i *buildkitImage) Run(vol volume.Volume, opts options, cmdArgs []string) error {
+	i.vol = vol
+
+	step := strings.Join(cmdArgs, " ")
+	if opts.WorkDir != "" {
+		step = fmt.Sprintf("cd %s && %s", opts.WorkDir, step)
+	}
+
+	return i.build(step)
+}
+
+// buildkitBuildStage and buildkitExportStage name the two stages
+// buildkitDockerfile renders
+const (
+	buildkitBuildStage  = "build"
+	buildkitExportStage = "export"
+)
+
+// buildkitDockerfile renders a two-stage Dockerfile for a single build
+// step run inside workDir on top of base. BuildKit's local exporter always
+// exports a stage's entire rootfs, not a subtree of it, so the last stage
+// starts FROM scratch and COPIes --from=build only workDir, scoping the
+// export to the work directory instead of dumping the whole base image
+// (zig toolchain, qemu-static, ...) on top of it.
+func buildkitDockerfile(base, workDir string, env map[string]string, step string) string {
+	dockerfile := fmt.Sprintf("FROM %s AS %s\nCOPY . %s\n", base, buildkitBuildStage, workDir)
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		dockerfile += fmt.Sprintf("ENV %s=%s\n", k, env[k])
+	}
+
+	dockerfile += fmt.Sprintf("RUN %s\n", step)
+	dockerfile += fmt.Sprintf("\nFROM scratch AS %s\nCOPY --from=%s %s /\n", buildkitExportStage, buildkitBuildStage, workDir)
+
+	return dockerfile
+}
+
+// buildkitArgs returns the buildctl build arguments that submit a
+// buildkitDockerfile for os/arch over stdin, exporting buildkitExportStage's
+// root back to workDirHost
+func buildkitArgs(os string, arch Architecture, workDirHost string) []string {
+	return []string{
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=" + workDirHost,
+		"--local", "dockerfile=-",
+		"--opt", fmt.Sprintf("platform=%s/%s", os, arch),
+		"--opt", "target=" + buildkitExportStage,
+		"--output", "type=local,dest=" + workDirHost,
+	}
+}
+
+// build renders step as part of a single-stage-plus-export Dockerfile and
+// submits it to buildctl as an LLB graph. The work directory is sent to
+// buildctl as the local build context, COPYed into the build stage and
+// exported back out once the build completes, so the next call's COPY picks up exactly the state
+// this call left behind - only the new step is replayed here, not the
+// history of prior Run calls, since those are already baked into the work
+// directory on disk.
+func (i *buildkitImage) build(step string) error {
+	dockerfile := buildkitDockerfile(i.base, i.vol.WorkDirContainer(), i.env, step)
+
+	cmd := exec.Command("buildctl", buildkitArgs(i.os, i.arch, i.vol.WorkDirHost())...)
+	cmd.Stdin = strings.NewReader(dockerfile)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("buildctl build: %v\n%s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestResolveContainerEngine covers the engineBuildah/engineBuildKit/default
+// branches only; the docker/podman branch defers to newContainerEngine,
+// which talks to a real docker or podman binary and isn't exercised here.
+func TestResolveContainerEngine(t *testing.T) {
+	tests := []struct {
+		flag    EngineFlag
+		want    interface{}
+		wantErr bool
+	}{
+		{flag: engineBuildah, want: &buildahEngine{}},
+		{flag: engineBuildKit, want: &buildkitEngine{}},
+		{flag: EngineFlag("crun"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.flag), func(t *testing.T) {
+			got, err := resolveContainerEngine(Context{}, tt.flag)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveContainerEngine(%q) = %v, want error", tt.flag, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveContainerEngine(%q) returned error: %v", tt.flag, err)
+			}
+
+			switch tt.want.(type) {
+			case *buildahEngine:
+				if _, ok := got.(*buildahEngine); !ok {
+					t.Errorf("resolveContainerEngine(%q) = %T, want *buildahEngine", tt.flag, got)
+				}
+			case *buildkitEngine:
+				if _, ok := got.(*buildkitEngine); !ok {
+					t.Errorf("resolveContainerEngine(%q) = %T, want *buildkitEngine", tt.flag, got)
+				}
+			}
+		})
+	}
+}
+
+// TestBuildkitDockerfileScopesExport guards against the export stage
+// dumping the whole base image's rootfs: the final stage must be FROM
+// scratch and only COPY --from=build the work directory, not the build
+// stage's entire filesystem.
+func TestBuildkitDockerfileScopesExport(t *testing.T) {
+	dockerfile := buildkitDockerfile("ghcr.io/herugen/fyne-cross-images-linux:latest", "/work", map[string]string{
+		"GOOS":   "linux",
+		"GOARCH": "amd64",
+	}, "tar -xf foo.tar.xz")
+
+	wantLines := []string{
+		"FROM ghcr.io/herugen/fyne-cross-images-linux:latest AS build",
+		"COPY . /work",
+		"ENV GOARCH=amd64",
+		"ENV GOOS=linux",
+		"RUN tar -xf foo.tar.xz",
+		"FROM scratch AS export",
+		"COPY --from=build /work /",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(dockerfile, want) {
+			t.Errorf("buildkitDockerfile() missing line %q, got:\n%s", want, dockerfile)
+		}
+	}
+
+	if strings.Contains(dockerfile, "COPY --from=build /\n") {
+		t.Errorf("buildkitDockerfile() exports the whole build stage rootfs instead of just the work dir:\n%s", dockerfile)
+	}
+}
+
+func TestBuildkitArgs(t *testing.T) {
+	args := buildkitArgs(linuxOS, ArchAmd64, "/host/work")
+
+	want := []string{
+		"build",
+		"--frontend", "dockerfile.v0",
+		"--local", "context=/host/work",
+		"--local", "dockerfile=-",
+		"--opt", "platform=linux/amd64",
+		"--opt", "target=export",
+		"--output", "type=local,dest=/host/work",
+	}
+
+	if len(args) != len(want) {
+		t.Fatalf("buildkitArgs() = %v, want %v", args, want)
+	}
+	for i := range args {
+		if args[i] != want[i] {
+			t.Errorf("buildkitArgs()[%d] = %q, want %q", i, args[i], want[i])
+		}
+	}
+}
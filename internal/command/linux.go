@@ -3,8 +3,10 @@ package command
 import (
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/fyne-io/fyne-cross/internal/log"
+	"github.com/fyne-io/fyne-cross/internal/manifest"
 	"github.com/fyne-io/fyne-cross/internal/volume"
 )
 
@@ -16,17 +18,102 @@ const (
 	linuxImage386   = "ghcr.io/herugen/fyne-cross-images-linux:latest"
 	linuxImageArm64 = "ghcr.io/herugen/fyne-cross-images-linux:latest"
 	linuxImageArm   = "ghcr.io/herugen/fyne-cross-images-linux:latest"
+
+	// linuxImageMusl is the fyne-cross image used for musl/Alpine targets
+	linuxImageMuslAmd64 = "ghcr.io/herugen/fyne-cross-images-linux-musl:latest"
+	linuxImageMusl386   = "ghcr.io/herugen/fyne-cross-images-linux-musl:latest"
+	linuxImageMuslArm64 = "ghcr.io/herugen/fyne-cross-images-linux-musl:latest"
+	linuxImageMuslArm   = "ghcr.io/herugen/fyne-cross-images-linux-musl:latest"
+
+	// libcGnu builds against the host's glibc, dynamically linked (default)
+	libcGnu = "gnu"
+	// libcMusl builds a statically-linked musl binary suitable for Alpine/distroless
+	libcMusl = "musl"
+
+	// formatTarXz is the default package format, produced by fynePackage/fyneRelease
+	formatTarXz = "tar.xz"
+	// formatAppImage packages the app as a self-contained AppImage
+	formatAppImage = "appimage"
+	// formatFlatpak packages the app as a Flatpak
+	formatFlatpak = "flatpak"
+	// formatDeb packages the app as a Debian package
+	formatDeb = "deb"
+	// formatRpm packages the app as an RPM package
+	formatRpm = "rpm"
+
+	// smokeTestDefaultArgs are the arguments passed to the built binary
+	// when -smoke-test is set without -smoke-test-args
+	smokeTestDefaultArgs = "--help"
+	// smokeTestDefaultTimeout bounds, in seconds, how long the smoke test
+	// may run before it is considered hung
+	smokeTestDefaultTimeout = 30
 )
 
+// linuxFormatSupported defines the package formats the linux command can emit
+var linuxFormatSupported = []string{formatTarXz, formatAppImage, formatFlatpak, formatDeb, formatRpm}
+
+// linuxFormatFlag collects the raw, comma-separated package formats passed
+// to -format; the flag is repeatable and accumulates across occurrences
+type linuxFormatFlag []string
+
+// String implements flag.Value
+func (f *linuxFormatFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements flag.Value, appending the comma-separated formats in value
+func (f *linuxFormatFlag) Set(value string) error {
+	*f = append(*f, strings.Split(value, ",")...)
+	return nil
+}
+
 var (
 	// linuxArchSupported defines the supported target architectures on linux
 	linuxArchSupported = []Architecture{ArchAmd64, Arch386, ArchArm, ArchArm64}
+
+	// linuxPlatformSupported defines the supported -platform tuples on linux
+	linuxPlatformSupported = []Platform{
+		{OS: linuxOS, Arch: ArchAmd64},
+		{OS: linuxOS, Arch: Arch386},
+		{OS: linuxOS, Arch: ArchArm, Variant: "v5"},
+		{OS: linuxOS, Arch: ArchArm, Variant: "v6"},
+		{OS: linuxOS, Arch: ArchArm, Variant: "v7"},
+		{OS: linuxOS, Arch: ArchArm64},
+	}
 )
 
 // linux build and package the fyne app for the linux OS
 type linux struct {
 	Images         []containerImage
 	defaultContext Context
+
+	// manifestName and manifestPush configure the optional OCI manifest
+	// list assembled from every architecture's output, enabled via
+	// -manifest/-manifest-push
+	manifestName string
+	manifestPush bool
+
+	// artifacts tracks, per image ID, the architecture and resulting
+	// package file name so packageManifest can assemble them once every
+	// Build has completed
+	artifacts map[string]*linuxArtifact
+
+	// formats lists the package formats to emit, set via -format
+	formats []string
+
+	// smokeTest, smokeTestArgs and smokeTestTimeout configure the optional
+	// pre-packaging smoke test, enabled via -smoke-test
+	smokeTest        bool
+	smokeTestArgs    string
+	smokeTestTimeout int
+}
+
+// linuxArtifact records the platform, libc flavor and package produced for a
+// single containerImage, keyed by image ID in linux.artifacts
+type linuxArtifact struct {
+	platform Platform
+	libc     string
+	archive  string
 }
 
 var _ platformBuilder = (*linux)(nil)
@@ -46,7 +133,41 @@ func (cmd *linux) Description() string {
 }
 
 func (cmd *linux) Run() error {
-	return commonRun(cmd.defaultContext, cmd.Images, cmd)
+	if err := commonRun(cmd.defaultContext, cmd.Images, cmd); err != nil {
+		return err
+	}
+
+	if cmd.manifestName == "" {
+		return nil
+	}
+
+	return cmd.packageManifest()
+}
+
+// packageManifest assembles the per-arch artifacts recorded in cmd.artifacts
+// into an OCI image index / manifest list under the dist directory
+func (cmd *linux) packageManifest() error {
+	images := make([]manifest.Image, 0, len(cmd.Images))
+	for _, image := range cmd.Images {
+		art, ok := cmd.artifacts[image.ID()]
+		if !ok || art.archive == "" {
+			continue
+		}
+
+		images = append(images, manifest.Image{
+			OS:      art.platform.OS,
+			Arch:    string(art.platform.Arch),
+			Variant: art.platform.Variant,
+			Archive: volume.JoinPathHost(cmd.defaultContext.TmpDirHost(), image.ID(), art.archive),
+		})
+	}
+
+	_, err := manifest.Write(images, manifest.Options{
+		Name: cmd.manifestName,
+		Dist: cmd.defaultContext.DistDirHost(),
+		Push: cmd.manifestPush,
+	})
+	return err
 }
 
 // Parse parses the arguments and set the usage for the command
@@ -60,7 +181,17 @@ func (cmd *linux) Parse(args []string) error {
 		CommonFlags: commonFlags,
 		TargetArch:  &targetArchFlag{runtime.GOARCH},
 	}
-	flagSet.Var(flags.TargetArch, "arch", fmt.Sprintf(`List of target architecture to build separated by comma. Supported arch: %s`, linuxArchSupported))
+	flagSet.Var(flags.TargetArch, "arch", fmt.Sprintf(`(deprecated: use -platform) List of target architecture to build separated by comma. Supported arch: %s`, linuxArchSupported))
+	flagSet.Var(&flags.TargetPlatform, "platform", fmt.Sprintf(`List of target platform to build separated by comma, in "os/arch[/variant]" form, or "all". Supported platform: %s`, linuxPlatformSupported))
+	flagSet.BoolVar(&flags.AllArchs, "all-archs", false, fmt.Sprintf("Build for every supported architecture (%s), shortcut for -arch with all values", linuxArchSupported))
+	flagSet.StringVar(&flags.Manifest, "manifest", "", "Name of an OCI image index to assemble from the per-arch tar.xz outputs once every architecture has built. When -manifest-push is set this doubles as the registry reference to push to (e.g. \"ghcr.io/user/app:latest\")")
+	flagSet.BoolVar(&flags.ManifestPush, "manifest-push", false, "Push the -manifest image index to the registry named by -manifest once it is written to the dist directory. Requires skopeo on PATH")
+	flagSet.StringVar(&flags.Libc, "libc", libcGnu, fmt.Sprintf("C library to link against: %q or %q. %q produces a statically-linked binary suitable for Alpine/distroless", libcGnu, libcMusl, libcMusl))
+	flagSet.Var(&flags.Formats, "format", fmt.Sprintf("Package format(s) to emit, separated by comma. Repeatable. Supported: %s (default %q)", linuxFormatSupported, formatTarXz))
+	flagSet.BoolVar(&flags.SmokeTest, "smoke-test", false, "Run the built binary under qemu (or natively, for the host arch) before packaging, failing the build if it exits non-zero or hangs")
+	flagSet.StringVar(&flags.SmokeTestArgs, "smoke-test-args", smokeTestDefaultArgs, "Arguments passed to the binary during -smoke-test")
+	flagSet.IntVar(&flags.SmokeTestTimeout, "smoke-test-timeout", smokeTestDefaultTimeout, "Seconds to wait for -smoke-test to exit before considering it hung")
+	flagSet.StringVar(&flags.Engine, "engine", string(engineDocker), fmt.Sprintf("Container engine backend to use. Supported: %s", engineSupported))
 
 	flagSet.Usage = cmd.Usage
 	flagSet.Parse(args)
@@ -92,20 +223,251 @@ func (cmd *linux) Build(image containerImage) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("could not package the Fyne app: %v", err)
 	}
+
+	// Tag the tarball name with the libc flavor so gnu and musl builds of
+	// the same arch can coexist in the dist dir
+	finalName := packageName
+	if art, ok := cmd.artifacts[image.ID()]; ok && art.libc == libcMusl {
+		finalName = fmt.Sprintf("%s-linux-%s-musl.tar.xz", cmd.defaultContext.Name, art.platform.Arch)
+	}
+
+	// Extract the resulting executable from the tarball before it moves out
+	// of the work dir, so the smoke test below can exercise it while it's
+	// still sitting where fynePackage/fyneRelease left it
+	image.Run(cmd.defaultContext.Volume,
+		options{WorkDir: volume.JoinPathContainer(cmd.defaultContext.BinDirContainer(), image.ID())},
+		[]string{"tar", "-xf",
+			volume.JoinPathContainer(cmd.defaultContext.WorkDirContainer(), packageName),
+			"--strip-components=3", "usr/local/bin"})
+
+	if art, ok := cmd.artifacts[image.ID()]; ok {
+		art.archive = finalName
+	}
+
+	if cmd.smokeTest {
+		if err := cmd.runSmokeTest(image); err != nil {
+			return "", err
+		}
+	}
+
 	image.Run(cmd.defaultContext.Volume, options{}, []string{
 		"mv",
 		volume.JoinPathContainer(cmd.defaultContext.WorkDirContainer(), packageName),
-		volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), packageName),
+		volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), finalName),
 	})
 
-	// Extract the resulting executable from the tarball
-	image.Run(cmd.defaultContext.Volume,
+	if err := cmd.packageExtraFormats(image, finalName); err != nil {
+		return "", err
+	}
+
+	return finalName, nil
+}
+
+// packageExtraFormats runs the post-package pipeline for every format
+// requested via -format besides the default tar.xz, writing each artifact
+// to dist/<arch>/
+func (cmd *linux) packageExtraFormats(image containerImage, archiveName string) error {
+	for _, format := range cmd.formats {
+		var err error
+
+		switch format {
+		case formatTarXz:
+			continue
+		case formatAppImage:
+			err = cmd.packageAppImage(image, archiveName)
+		case formatFlatpak:
+			err = cmd.packageFlatpak(image, archiveName)
+		case formatDeb:
+			err = cmd.packageWithFpm(image, "deb")
+		case formatRpm:
+			err = cmd.packageWithFpm(image, "rpm")
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not package %s for %s: %v. Install the fyne-cross linux-packaging image to enable this format", format, image.ID(), err)
+		}
+	}
+
+	return nil
+}
+
+// packageAppImage assembles an AppImage from the extracted usr/ tree of
+// archiveName using appimagetool. appimagetool requires the AppDir root to
+// hold, in addition to the usr/ tree, a top-level AppRun entry point, a
+// top-level <name>.desktop and a top-level icon, so those are synthesized
+// from the extracted desktop file and icon before it runs.
+func (cmd *linux) packageAppImage(image containerImage, archiveName string) error {
+	appDir := volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), "AppDir")
+	name := cmd.defaultContext.Name
+
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{"mkdir", "-p", appDir}); err != nil {
+		return err
+	}
+
+	// Keep the usr/ prefix: appimagetool expects the AppDir root to contain
+	// the full usr/ tree alongside AppRun and the top-level desktop/icon
+	if err := image.Run(cmd.defaultContext.Volume, options{WorkDir: appDir}, []string{
+		"tar", "-xf", volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), archiveName),
+		"usr",
+	}); err != nil {
+		return err
+	}
+
+	desktopFile := volume.JoinPathContainer(appDir, "usr", "local", "share", "applications", name+".desktop")
+	iconFile := volume.JoinPathContainer(appDir, "usr", "local", "share", "pixmaps", name+".png")
+	appRun := volume.JoinPathContainer(appDir, "AppRun")
+
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"cp", desktopFile, volume.JoinPathContainer(appDir, name+".desktop"),
+	}); err != nil {
+		return err
+	}
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"cp", iconFile, volume.JoinPathContainer(appDir, name+".png"),
+	}); err != nil {
+		return err
+	}
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"sh", "-c", fmt.Sprintf("cat > %s <<'FYNE_CROSS_EOF'\n#!/bin/sh\nHERE=$(dirname \"$(readlink -f \"$0\")\")\nexec \"$HERE/usr/local/bin/%s\" \"$@\"\nFYNE_CROSS_EOF\nchmod +x %s", appRun, name, appRun),
+	}); err != nil {
+		return err
+	}
+
+	outName := fmt.Sprintf("%s-%s.AppImage", name, image.ID())
+
+	return image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"appimagetool", appDir,
+		volume.JoinPathContainer(cmd.defaultContext.DistDirContainer(), image.ID(), outName),
+	})
+}
+
+// flatpakManifestTemplate is a minimal single-module Flatpak manifest
+// referencing the extracted binary and desktop file produced by fynePackage
+const flatpakManifestTemplate = `{
+  "app-id": "%[1]s",
+  "runtime": "org.freedesktop.Platform",
+  "runtime-version": "23.08",
+  "sdk": "org.freedesktop.Sdk",
+  "command": "%[2]s",
+  "modules": [
+    {
+      "name": "%[2]s",
+      "buildsystem": "simple",
+      "build-commands": [
+        "install -Dm755 bin/%[2]s /app/bin/%[2]s",
+        "install -Dm644 share/applications/%[2]s.desktop /app/share/applications/%[1]s.desktop"
+      ],
+      "sources": [
+        { "type": "dir", "path": "." }
+      ]
+    }
+  ]
+}
+`
+
+// packageFlatpak runs flatpak-builder with a generated manifest over the
+// extracted tree of archiveName, building the app into dist/<image ID>/flatpak.
+// The build directory is not exported to a repository or to a single-file
+// .flatpak bundle.
+func (cmd *linux) packageFlatpak(image containerImage, archiveName string) error {
+	appID := fmt.Sprintf("io.fynecross.%s", cmd.defaultContext.Name)
+	buildDir := volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), "flatpak-src")
+
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{"mkdir", "-p", buildDir}); err != nil {
+		return err
+	}
+
+	// Extract the whole usr/local tree, not just bin/: the manifest's
+	// build-commands also install the desktop file under share/applications
+	if err := image.Run(cmd.defaultContext.Volume, options{WorkDir: buildDir}, []string{
+		"tar", "-xf", volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), archiveName),
+		"--strip-components=2", "usr/local",
+	}); err != nil {
+		return err
+	}
+
+	manifestPath := volume.JoinPathContainer(buildDir, appID+".json")
+	manifestContents := fmt.Sprintf(flatpakManifestTemplate, appID, cmd.defaultContext.Name)
+
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"sh", "-c", fmt.Sprintf("cat > %s <<'FYNE_CROSS_EOF'\n%sFYNE_CROSS_EOF\n", manifestPath, manifestContents),
+	}); err != nil {
+		return err
+	}
+
+	return image.Run(cmd.defaultContext.Volume, options{WorkDir: buildDir}, []string{
+		"flatpak-builder", "--force-clean",
+		volume.JoinPathContainer(cmd.defaultContext.DistDirContainer(), image.ID(), "flatpak"),
+		manifestPath,
+	})
+}
+
+// packageWithFpm packages the extracted binary as a deb or rpm using fpm
+func (cmd *linux) packageWithFpm(image containerImage, format string) error {
+	name := fmt.Sprintf("%s.%s", cmd.defaultContext.Name, format)
+
+	return image.Run(cmd.defaultContext.Volume,
 		options{WorkDir: volume.JoinPathContainer(cmd.defaultContext.BinDirContainer(), image.ID())},
-		[]string{"tar", "-xf",
-			volume.JoinPathContainer(cmd.defaultContext.TmpDirContainer(), image.ID(), packageName),
-			"--strip-components=3", "usr/local/bin"})
+		[]string{
+			"fpm", "-s", "dir", "-t", format, "-f",
+			"-n", cmd.defaultContext.Name,
+			"-p", volume.JoinPathContainer(cmd.defaultContext.DistDirContainer(), image.ID(), name),
+			fmt.Sprintf("%s=/usr/local/bin/%s", cmd.defaultContext.Name, cmd.defaultContext.Name),
+		})
+}
+
+// runSmokeTest runs the just-built binary inside image, under qemu-user
+// emulation unless its architecture matches the host, and fails the build
+// if it exits non-zero or runs past smokeTestTimeout
+func (cmd *linux) runSmokeTest(image containerImage) error {
+	art, ok := cmd.artifacts[image.ID()]
+	if !ok {
+		return nil
+	}
+
+	binPath := volume.JoinPathContainer(cmd.defaultContext.BinDirContainer(), image.ID(), cmd.defaultContext.Name)
+
+	args := cmd.smokeTestArgs
+	if args == "" {
+		args = smokeTestDefaultArgs
+	}
 
-	return packageName, nil
+	invocation := fmt.Sprintf("%s %s", binPath, args)
+	if string(art.platform.Arch) != runtime.GOARCH {
+		invocation = fmt.Sprintf("qemu-%s-static %s", qemuArchName(art.platform.Arch), invocation)
+	}
+
+	timeout := cmd.smokeTestTimeout
+	if timeout <= 0 {
+		timeout = smokeTestDefaultTimeout
+	}
+
+	log.Infof("[i] Running smoke test: %s", invocation)
+
+	if err := image.Run(cmd.defaultContext.Volume, options{}, []string{
+		"timeout", fmt.Sprintf("%ds", timeout), "sh", "-c", invocation,
+	}); err != nil {
+		return fmt.Errorf("smoke test failed for %s: %v", art.platform, err)
+	}
+
+	return nil
+}
+
+// qemuArchName maps a fyne-cross Architecture to the arch suffix used by
+// qemu-user-static binaries (qemu-<arch>-static)
+func qemuArchName(arch Architecture) string {
+	switch arch {
+	case ArchArm:
+		return "arm"
+	case ArchArm64:
+		return "aarch64"
+	case Arch386:
+		return "i386"
+	case ArchAmd64:
+		return "x86_64"
+	default:
+		return string(arch)
+	}
 }
 
 // Usage displays the command usage
@@ -135,12 +497,86 @@ type linuxFlags struct {
 	*CommonFlags
 
 	// TargetArch represents a list of target architecture to build on separated by comma
+	//
+	// Deprecated: use TargetPlatform instead
 	TargetArch *targetArchFlag
+
+	// TargetPlatform represents a list of "os/arch[/variant]" target
+	// platforms to build on, separated by comma, or "all"
+	TargetPlatform PlatformFlag
+
+	// AllArchs builds for every architecture in linuxArchSupported,
+	// equivalent to passing all of them to -arch
+	AllArchs bool
+
+	// Manifest, when set, is the name of the OCI image index assembled
+	// from the per-arch tar.xz outputs once every Build has completed
+	Manifest string
+
+	// ManifestPush pushes the assembled Manifest to a registry
+	ManifestPush bool
+
+	// Libc selects the C library to link the binary against: libcGnu
+	// (default, dynamic) or libcMusl (static)
+	Libc string
+
+	// Formats lists the package formats to emit, in addition to the
+	// default tar.xz
+	Formats linuxFormatFlag
+
+	// SmokeTest, SmokeTestArgs and SmokeTestTimeout configure the optional
+	// pre-packaging smoke test
+	SmokeTest        bool
+	SmokeTestArgs    string
+	SmokeTestTimeout int
+
+	// Engine selects the container engine backend used to run build steps.
+	//
+	// This belongs on CommonFlags so every platform command shares one
+	// -engine flag, but CommonFlags lives outside this package's linux.go/
+	// engine.go/platform.go slice and isn't touched here; it stays on
+	// linuxFlags until that file is in scope.
+	Engine string
+}
+
+// libcFromFlag normalizes the -libc flag value, defaulting an empty raw to
+// libcGnu, and rejects anything other than libcGnu or libcMusl
+func libcFromFlag(raw string) (string, error) {
+	if raw == "" {
+		return libcGnu, nil
+	}
+	if raw != libcGnu && raw != libcMusl {
+		return "", fmt.Errorf("unsupported -libc %q, expected %q or %q", raw, libcGnu, libcMusl)
+	}
+	return raw, nil
+}
+
+// formatsFromFlag normalizes the -format flag value, defaulting an empty raw
+// to [formatTarXz], and rejects any format not in linuxFormatSupported
+func formatsFromFlag(raw []string) ([]string, error) {
+	if len(raw) == 0 {
+		return []string{formatTarXz}, nil
+	}
+
+	for _, format := range raw {
+		supported := false
+		for _, s := range linuxFormatSupported {
+			if s == format {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported -format %q, supported: %s", format, linuxFormatSupported)
+		}
+	}
+
+	return raw, nil
 }
 
 // setupContainerImages returns the command ContainerImages for a linux target
 func (cmd *linux) setupContainerImages(flags *linuxFlags, args []string) error {
-	targetArch, err := targetArchFromFlag(*flags.TargetArch, linuxArchSupported)
+	targetPlatform, err := cmd.resolveTargetPlatform(flags)
 	if err != nil {
 		return fmt.Errorf("could not make build context for %s OS: %s", linuxOS, err)
 	}
@@ -150,43 +586,131 @@ func (cmd *linux) setupContainerImages(flags *linuxFlags, args []string) error {
 		return err
 	}
 
+	libc, err := libcFromFlag(flags.Libc)
+	if err != nil {
+		return err
+	}
+
+	if flags.ManifestPush && flags.Manifest == "" {
+		return fmt.Errorf("-manifest-push requires -manifest")
+	}
+
+	formats, err := formatsFromFlag([]string(flags.Formats))
+	if err != nil {
+		return err
+	}
+
 	cmd.defaultContext = ctx
-	runner, err := newContainerEngine(ctx)
+	cmd.manifestName = flags.Manifest
+	cmd.manifestPush = flags.ManifestPush
+	cmd.formats = formats
+	cmd.smokeTest = flags.SmokeTest
+	cmd.smokeTestArgs = flags.SmokeTestArgs
+	cmd.smokeTestTimeout = flags.SmokeTestTimeout
+
+	runner, err := resolveContainerEngine(ctx, EngineFlag(flags.Engine))
 	if err != nil {
 		return err
 	}
+	for _, platform := range targetPlatform {
+		if !runner.SupportsPlatform(platform.OS, platform.Arch) {
+			return fmt.Errorf("engine %q does not support platform %s", flags.Engine, platform)
+		}
+	}
 
-	for _, arch := range targetArch {
+	for _, platform := range targetPlatform {
+		arch := platform.Arch
 		var image containerImage
 
 		switch arch {
 		case ArchAmd64:
-			image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageAmd64))
+			if libc == libcMusl {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageMuslAmd64), platform.Variant)
+				image.SetEnv("CC", "zig cc -target x86_64-linux-musl -static")
+				image.SetEnv("CXX", "zig c++ -target x86_64-linux-musl -static")
+			} else {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageAmd64), platform.Variant)
+				image.SetEnv("CC", "zig cc -target x86_64-linux-gnu -isystem /usr/include -L/usr/lib/x86_64-linux-gnu")
+				image.SetEnv("CXX", "zig c++ -target x86_64-linux-gnu -isystem /usr/include -L/usr/lib/x86_64-linux-gnu")
+			}
 			image.SetEnv("GOARCH", "amd64")
-			image.SetEnv("CC", "zig cc -target x86_64-linux-gnu -isystem /usr/include -L/usr/lib/x86_64-linux-gnu")
-			image.SetEnv("CXX", "zig c++ -target x86_64-linux-gnu -isystem /usr/include -L/usr/lib/x86_64-linux-gnu")
 		case Arch386:
-			image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImage386))
+			if libc == libcMusl {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageMusl386), platform.Variant)
+				image.SetEnv("CC", "zig cc -target x86-linux-musl -static")
+				image.SetEnv("CXX", "zig c++ -target x86-linux-musl -static")
+			} else {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImage386), platform.Variant)
+				image.SetEnv("CC", "zig cc -target x86-linux-gnu -isystem /usr/include -L/usr/lib/i386-linux-gnu")
+				image.SetEnv("CXX", "zig c++ -target x86-linux-gnu -isystem /usr/include -L/usr/lib/i386-linux-gnu")
+			}
 			image.SetEnv("GOARCH", "386")
-			image.SetEnv("CC", "zig cc -target x86-linux-gnu -isystem /usr/include -L/usr/lib/i386-linux-gnu")
-			image.SetEnv("CXX", "zig c++ -target x86-linux-gnu -isystem /usr/include -L/usr/lib/i386-linux-gnu")
 		case ArchArm:
-			image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageArm))
+			goarm, err := goarmFromVariant(platform.Variant)
+			if err != nil {
+				return fmt.Errorf("could not make build context for %s: %s", platform, err)
+			}
+
+			if libc == libcMusl {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageMuslArm), platform.Variant)
+				image.SetEnv("CC", "zig cc -target arm-linux-musleabihf -static")
+				image.SetEnv("CXX", "zig c++ -target arm-linux-musleabihf -static")
+			} else {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageArm), platform.Variant)
+				image.SetEnv("CC", "zig cc -target arm-linux-gnueabihf -isystem /usr/include -L/usr/lib/arm-linux-gnueabihf")
+				image.SetEnv("CXX", "zig c++ -target arm-linux-gnueabihf -isystem /usr/include -L/usr/lib/arm-linux-gnueabihf")
+			}
 			image.SetEnv("GOARCH", "arm")
-			image.SetEnv("GOARM", "7")
-			image.SetEnv("CC", "zig cc -target arm-linux-gnueabihf -isystem /usr/include -L/usr/lib/arm-linux-gnueabihf")
-			image.SetEnv("CXX", "zig c++ -target arm-linux-gnueabihf -isystem /usr/include -L/usr/lib/arm-linux-gnueabihf")
+			image.SetEnv("GOARM", goarm)
 		case ArchArm64:
-			image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageArm64))
+			if libc == libcMusl {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageMuslArm64), platform.Variant)
+				image.SetEnv("CC", "zig cc -target aarch64-linux-musl -static")
+				image.SetEnv("CXX", "zig c++ -target aarch64-linux-musl -static")
+			} else {
+				image = runner.createContainerImage(arch, linuxOS, overrideDockerImage(flags.CommonFlags, linuxImageArm64), platform.Variant)
+				image.SetEnv("CC", "zig cc -target aarch64-linux-gnu -isystem /usr/include -L/usr/lib/aarch64-linux-gnu")
+				image.SetEnv("CXX", "zig c++ -target aarch64-linux-gnu -isystem /usr/include -L/usr/lib/aarch64-linux-gnu")
+			}
 			image.SetEnv("GOARCH", "arm64")
-			image.SetEnv("CC", "zig cc -target aarch64-linux-gnu -isystem /usr/include -L/usr/lib/aarch64-linux-gnu")
-			image.SetEnv("CXX", "zig c++ -target aarch64-linux-gnu -isystem /usr/include -L/usr/lib/aarch64-linux-gnu")
 		}
 
 		image.SetEnv("GOOS", "linux")
 
 		cmd.Images = append(cmd.Images, image)
+
+		if cmd.artifacts == nil {
+			cmd.artifacts = make(map[string]*linuxArtifact)
+		}
+		cmd.artifacts[image.ID()] = &linuxArtifact{platform: platform, libc: libc}
 	}
 
 	return nil
 }
+
+// resolveTargetPlatform resolves the -platform, -all-archs and (deprecated)
+// -arch flags into the list of platforms to build, in that order of
+// precedence.
+func (cmd *linux) resolveTargetPlatform(flags *linuxFlags) ([]Platform, error) {
+	if len(flags.TargetPlatform) > 0 {
+		return platformsFromFlag(flags.TargetPlatform, linuxPlatformSupported)
+	}
+
+	var targetArch []Architecture
+	if flags.AllArchs {
+		targetArch = linuxArchSupported
+	} else {
+		var err error
+		targetArch, err = targetArchFromFlag(*flags.TargetArch, linuxArchSupported)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	platforms := make([]Platform, len(targetArch))
+	for i, arch := range targetArch {
+		platforms[i] = Platform{OS: linuxOS, Arch: arch}
+	}
+
+	return platforms, nil
+}
@@ -0,0 +1,90 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLibcFromFlag(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: libcGnu},
+		{raw: libcGnu, want: libcGnu},
+		{raw: libcMusl, want: libcMusl},
+		{raw: "glibc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := libcFromFlag(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("libcFromFlag(%q) = %q, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("libcFromFlag(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("libcFromFlag(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatsFromFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty defaults to tar.xz", raw: nil, want: []string{formatTarXz}},
+		{name: "single supported", raw: []string{formatAppImage}, want: []string{formatAppImage}},
+		{name: "multiple supported", raw: []string{formatDeb, formatRpm}, want: []string{formatDeb, formatRpm}},
+		{name: "unsupported", raw: []string{"snap"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatsFromFlag(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("formatsFromFlag(%v) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("formatsFromFlag(%v) returned error: %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("formatsFromFlag(%v) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQemuArchName(t *testing.T) {
+	tests := []struct {
+		arch Architecture
+		want string
+	}{
+		{arch: ArchArm, want: "arm"},
+		{arch: ArchArm64, want: "aarch64"},
+		{arch: Arch386, want: "i386"},
+		{arch: ArchAmd64, want: "x86_64"},
+		{arch: Architecture("riscv64"), want: "riscv64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.arch), func(t *testing.T) {
+			if got := qemuArchName(tt.arch); got != tt.want {
+				t.Errorf("qemuArchName(%q) = %q, want %q", tt.arch, got, tt.want)
+			}
+		})
+	}
+}
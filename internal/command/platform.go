@@ -0,0 +1,105 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Platform is a single, parsed target tuple in the form "os/arch[/variant]",
+// following the docker-buildx convention (e.g. "linux/amd64", "linux/arm/v7").
+type Platform struct {
+	OS      string
+	Arch    Architecture
+	Variant string
+}
+
+// String returns the canonical "os/arch[/variant]" representation of p
+func (p Platform) String() string {
+	if p.Variant == "" {
+		return fmt.Sprintf("%s/%s", p.OS, p.Arch)
+	}
+	return fmt.Sprintf("%s/%s/%s", p.OS, p.Arch, p.Variant)
+}
+
+// PlatformFlag collects the raw, comma-separated "os/arch[/variant]" values
+// passed to -platform. Use platformsFromFlag to resolve it against a
+// command's list of supported platforms.
+type PlatformFlag []string
+
+// String implements flag.Value
+func (f *PlatformFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements flag.Value, appending the comma-separated tuples in value
+func (f *PlatformFlag) Set(value string) error {
+	*f = append(*f, strings.Split(value, ",")...)
+	return nil
+}
+
+// platformsFromFlag resolves flag against the platforms a command supports.
+// The special value "all" expands to every entry in supported.
+func platformsFromFlag(flag PlatformFlag, supported []Platform) ([]Platform, error) {
+	if len(flag) == 0 {
+		return nil, nil
+	}
+
+	if len(flag) == 1 && flag[0] == "all" {
+		return supported, nil
+	}
+
+	platforms := make([]Platform, 0, len(flag))
+	for _, raw := range flag {
+		platform, err := parsePlatform(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, s := range supported {
+			if s.OS == platform.OS && s.Arch == platform.Arch &&
+				(platform.Variant == "" || s.Variant == platform.Variant) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported platform %q, supported: %s", raw, supported)
+		}
+
+		platforms = append(platforms, platform)
+	}
+
+	return platforms, nil
+}
+
+// parsePlatform parses a single "os/arch[/variant]" tuple
+func parsePlatform(raw string) (Platform, error) {
+	parts := strings.Split(raw, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Platform{}, fmt.Errorf(`invalid platform %q, expected "os/arch" or "os/arch/variant"`, raw)
+	}
+
+	platform := Platform{OS: parts[0], Arch: Architecture(parts[1])}
+	if len(parts) == 3 {
+		platform.Variant = parts[2]
+	}
+
+	return platform, nil
+}
+
+// goarmFromVariant maps a docker-style arm variant (v6, v7, ...) to the
+// GOARM value expected by the Go toolchain. An empty variant defaults to v7,
+// matching fyne-cross' historical default for ArchArm.
+func goarmFromVariant(variant string) (string, error) {
+	switch variant {
+	case "", "v7":
+		return "7", nil
+	case "v6":
+		return "6", nil
+	case "v5":
+		return "5", nil
+	default:
+		return "", fmt.Errorf("unknown arm variant %q, expected one of v5, v6, v7", variant)
+	}
+}
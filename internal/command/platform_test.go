@@ -0,0 +1,110 @@
+package command
+
+import "testing"
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    Platform
+		wantErr bool
+	}{
+		{name: "os/arch", raw: "linux/amd64", want: Platform{OS: "linux", Arch: ArchAmd64}},
+		{name: "os/arch/variant", raw: "linux/arm/v7", want: Platform{OS: "linux", Arch: ArchArm, Variant: "v7"}},
+		{name: "missing arch", raw: "linux", wantErr: true},
+		{name: "too many parts", raw: "linux/arm/v7/extra", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePlatform(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePlatform(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePlatform(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePlatform(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlatformsFromFlag(t *testing.T) {
+	supported := []Platform{
+		{OS: "linux", Arch: ArchAmd64},
+		{OS: "linux", Arch: ArchArm, Variant: "v7"},
+	}
+
+	tests := []struct {
+		name    string
+		flag    PlatformFlag
+		want    []Platform
+		wantErr bool
+	}{
+		{name: "empty", flag: nil, want: nil},
+		{name: "all", flag: PlatformFlag{"all"}, want: supported},
+		{name: "single supported", flag: PlatformFlag{"linux/amd64"}, want: []Platform{{OS: "linux", Arch: ArchAmd64}}},
+		{name: "unsupported", flag: PlatformFlag{"linux/arm64"}, wantErr: true},
+		{name: "invalid tuple", flag: PlatformFlag{"linux"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := platformsFromFlag(tt.flag, supported)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("platformsFromFlag(%v) = %v, want error", tt.flag, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("platformsFromFlag(%v) returned error: %v", tt.flag, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("platformsFromFlag(%v) = %+v, want %+v", tt.flag, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("platformsFromFlag(%v)[%d] = %+v, want %+v", tt.flag, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGoarmFromVariant(t *testing.T) {
+	tests := []struct {
+		variant string
+		want    string
+		wantErr bool
+	}{
+		{variant: "", want: "7"},
+		{variant: "v7", want: "7"},
+		{variant: "v6", want: "6"},
+		{variant: "v5", want: "5"},
+		{variant: "v8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.variant, func(t *testing.T) {
+			got, err := goarmFromVariant(tt.variant)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("goarmFromVariant(%q) = %q, want error", tt.variant, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("goarmFromVariant(%q) returned error: %v", tt.variant, err)
+			}
+			if got != tt.want {
+				t.Errorf("goarmFromVariant(%q) = %q, want %q", tt.variant, got, tt.want)
+			}
+		})
+	}
+}
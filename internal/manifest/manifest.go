@@ -0,0 +1,245 @@
+// Package manifest assembles per-architecture build artifacts produced by
+// the platform commands (linux, darwin, windows, ...) into an OCI image
+// index / manifest list, optionally publishing it to a registry.
+package manifest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/fyne-io/fyne-cross/internal/log"
+)
+
+// Image describes a single per-architecture build artifact that should be
+// combined into a multi-arch OCI image index.
+type Image struct {
+	// OS is the target operating system, e.g. "linux"
+	OS string
+	// Arch is the target architecture, e.g. "amd64"
+	Arch string
+	// Variant is an optional architecture variant, e.g. "v7"
+	Variant string
+	// Archive is the path, on the host, to the packaged tar.xz for this arch
+	Archive string
+}
+
+// Options controls how the manifest list is produced and, optionally,
+// published.
+type Options struct {
+	// Name is the manifest list name, used as the output directory name
+	Name string
+	// Dist is the directory the manifest list is written into
+	Dist string
+	// Push, when true, publishes the assembled manifest list to a registry
+	// after it is written locally
+	Push bool
+}
+
+const (
+	mediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeImageConfig   = "application/vnd.oci.image.config.v1+json"
+	// mediaTypeBuildArtifact identifies the packaged tar.xz fyne-cross
+	// produces. It isn't one of the OCI-defined layer media types, which
+	// only cover tar/tar+gzip/tar+zstd content, and this archive is xz
+	// compressed and isn't an extractable rootfs layer either.
+	mediaTypeBuildArtifact = "application/vnd.fyne-cross.build-artifact.v1.tar+xz"
+)
+
+type descriptor struct {
+	MediaType string    `json:"mediaType"`
+	Digest    string    `json:"digest"`
+	Size      int64     `json:"size"`
+	Platform  *platform `json:"platform,omitempty"`
+}
+
+type platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+type index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Manifests     []descriptor `json:"manifests"`
+}
+
+// imageManifest wraps a single per-platform build artifact the way an OCI
+// image manifest wraps its layers, so the index references manifests rather
+// than raw blobs directly.
+type imageManifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+}
+
+// Write assembles an OCI image index from images and writes it, along with
+// the referenced blobs, to opts.Dist/opts.Name. It returns the path to the
+// written index.json. When opts.Push is set the assembled index is also
+// published to a registry.
+func Write(images []Image, opts Options) (string, error) {
+	if len(images) == 0 {
+		return "", fmt.Errorf("manifest: no images to assemble")
+	}
+
+	outDir := filepath.Join(opts.Dist, opts.Name)
+	blobsDir := filepath.Join(outDir, "blobs", "sha256")
+	if err := os.MkdirAll(blobsDir, 0755); err != nil {
+		return "", fmt.Errorf("could not create manifest output dir: %v", err)
+	}
+
+	idx := index{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeImageIndex,
+	}
+
+	for _, img := range images {
+		manifestDigest, manifestSize, err := writeImageManifest(img, blobsDir)
+		if err != nil {
+			return "", fmt.Errorf("could not add %s/%s to manifest: %v", img.OS, img.Arch, err)
+		}
+
+		idx.Manifests = append(idx.Manifests, descriptor{
+			MediaType: mediaTypeImageManifest,
+			Digest:    manifestDigest,
+			Size:      manifestSize,
+			Platform: &platform{
+				Architecture: img.Arch,
+				OS:           img.OS,
+				Variant:      img.Variant,
+			},
+		})
+	}
+
+	if err := os.WriteFile(filepath.Join(outDir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0644); err != nil {
+		return "", fmt.Errorf("could not write oci-layout: %v", err)
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not encode manifest index: %v", err)
+	}
+
+	indexPath := filepath.Join(outDir, "index.json")
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return "", fmt.Errorf("could not write manifest index: %v", err)
+	}
+
+	log.Infof("[i] Wrote multi-arch manifest for %d architectures to %s", len(images), outDir)
+
+	if opts.Push {
+		if err := push(outDir, opts.Name); err != nil {
+			return "", fmt.Errorf("could not push manifest %q: %v", opts.Name, err)
+		}
+	}
+
+	return indexPath, nil
+}
+
+// writeImageManifest writes img's archive as a build-artifact blob plus a
+// minimal config blob, wraps both in an OCI image manifest for img's
+// platform, and writes that manifest as a blob too. It returns the digest
+// and size of the image manifest blob, ready to reference from the index.
+func writeImageManifest(img Image, blobsDir string) (string, int64, error) {
+	layerDigest, layerSize, err := copyBlob(img.Archive, blobsDir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	configDigest, configSize, err := writeBlob([]byte("{}"), blobsDir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	manifest := imageManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeImageManifest,
+		Config: descriptor{
+			MediaType: mediaTypeImageConfig,
+			Digest:    configDigest,
+			Size:      configSize,
+		},
+		Layers: []descriptor{
+			{
+				MediaType: mediaTypeBuildArtifact,
+				Digest:    layerDigest,
+				Size:      layerSize,
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", 0, err
+	}
+
+	return writeBlob(data, blobsDir)
+}
+
+// copyBlob copies src into dir, naming it after its sha256 digest, and
+// returns the digest (prefixed "sha256:") and size of the copied content.
+func copyBlob(src, dir string) (string, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", 0, err
+	}
+	defer in.Close()
+
+	return writeBlobReader(in, dir)
+}
+
+// writeBlob writes data into dir, naming it after its sha256 digest, and
+// returns the digest (prefixed "sha256:") and size of the written content.
+func writeBlob(data []byte, dir string) (string, int64, error) {
+	return writeBlobReader(bytes.NewReader(data), dir)
+}
+
+// writeBlobReader drains r into dir, naming the result after its sha256
+// digest, and returns the digest (prefixed "sha256:") and size written.
+func writeBlobReader(r io.Reader, dir string) (string, int64, error) {
+	tmp, err := os.CreateTemp(dir, "blob-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	dst := filepath.Join(dir, digest)
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, err
+	}
+
+	return "sha256:" + digest, size, nil
+}
+
+// push publishes the OCI layout at outDir to name, a registry reference
+// (e.g. "ghcr.io/user/app:latest"), via skopeo. fyne-cross does not vendor a
+// registry client, so this wraps whichever OCI-aware tool the user already
+// has on PATH, the same way the buildah/buildkit engines wrap their CLIs.
+func push(outDir, name string) error {
+	cmd := exec.Command("skopeo", "copy", "--all", "oci:"+outDir, "docker://"+name)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("skopeo copy oci:%s docker://%s: %v\n%s", outDir, name, err, out)
+	}
+	return nil
+}
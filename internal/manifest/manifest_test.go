@@ -0,0 +1,119 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyBlob(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "empty", content: []byte{}},
+		{name: "small", content: []byte("fyne-cross")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			src := filepath.Join(dir, "src")
+			if err := os.WriteFile(src, tt.content, 0644); err != nil {
+				t.Fatalf("could not write src file: %v", err)
+			}
+
+			blobsDir := filepath.Join(dir, "blobs")
+			if err := os.MkdirAll(blobsDir, 0755); err != nil {
+				t.Fatalf("could not create blobs dir: %v", err)
+			}
+
+			digest, size, err := copyBlob(src, blobsDir)
+			if err != nil {
+				t.Fatalf("copyBlob(%q) returned error: %v", src, err)
+			}
+
+			sum := sha256.Sum256(tt.content)
+			wantDigest := "sha256:" + hex.EncodeToString(sum[:])
+			if digest != wantDigest {
+				t.Errorf("digest = %q, want %q", digest, wantDigest)
+			}
+			if size != int64(len(tt.content)) {
+				t.Errorf("size = %d, want %d", size, len(tt.content))
+			}
+
+			got, err := os.ReadFile(filepath.Join(blobsDir, wantDigest[len("sha256:"):]))
+			if err != nil {
+				t.Fatalf("could not read blob: %v", err)
+			}
+			if string(got) != string(tt.content) {
+				t.Errorf("blob content = %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	archive := filepath.Join(dir, "app-linux-amd64.tar.xz")
+	if err := os.WriteFile(archive, []byte("archive-content"), 0644); err != nil {
+		t.Fatalf("could not write archive: %v", err)
+	}
+
+	indexPath, err := Write([]Image{
+		{OS: "linux", Arch: "amd64", Archive: archive},
+		{OS: "linux", Arch: "arm", Variant: "v7", Archive: archive},
+	}, Options{Name: "myapp", Dist: dir})
+	if err != nil {
+		t.Fatalf("Write returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatalf("could not read index.json: %v", err)
+	}
+
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		t.Fatalf("could not decode index.json: %v", err)
+	}
+
+	if idx.MediaType != mediaTypeImageIndex {
+		t.Errorf("index mediaType = %q, want %q", idx.MediaType, mediaTypeImageIndex)
+	}
+	if len(idx.Manifests) != 2 {
+		t.Fatalf("len(idx.Manifests) = %d, want 2", len(idx.Manifests))
+	}
+
+	for _, m := range idx.Manifests {
+		if m.MediaType != mediaTypeImageManifest {
+			t.Errorf("manifest entry mediaType = %q, want %q", m.MediaType, mediaTypeImageManifest)
+		}
+		if m.Platform == nil || m.Platform.OS != "linux" {
+			t.Errorf("manifest entry platform = %+v, want linux platform", m.Platform)
+		}
+
+		blobPath := filepath.Join(dir, "myapp", "blobs", "sha256", m.Digest[len("sha256:"):])
+		blobData, err := os.ReadFile(blobPath)
+		if err != nil {
+			t.Fatalf("could not read referenced image manifest blob: %v", err)
+		}
+
+		var im imageManifest
+		if err := json.Unmarshal(blobData, &im); err != nil {
+			t.Fatalf("could not decode image manifest blob: %v", err)
+		}
+		if len(im.Layers) != 1 || im.Layers[0].MediaType != mediaTypeBuildArtifact {
+			t.Errorf("image manifest layers = %+v, want one %s layer", im.Layers, mediaTypeBuildArtifact)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "myapp", "oci-layout")); err != nil {
+		t.Errorf("oci-layout not written: %v", err)
+	}
+}